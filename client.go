@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrJobNotFound is returned by jenkinsClient methods that look up a job by
+// name when Jenkins reports it doesn't exist, so callers (e.g.
+// jenkins_job's Read) can distinguish "gone" from a transport/auth error.
+var ErrJobNotFound = errors.New("jenkins job not found")
+
+// jenkinsClient is the minimal Jenkins API surface the provider's resources
+// need. Resources receive it as their meta value; this package defines no
+// Provider that constructs one.
+type jenkinsClient struct {
+	BaseURL  string
+	Username string
+	APIToken string
+
+	httpClient *http.Client
+}
+
+func (c *jenkinsClient) client() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return http.DefaultClient
+}
+
+func (c *jenkinsClient) newRequest(ctx context.Context, method, url string, body string) (*http.Request, error) {
+	var reqBody *bytes.Buffer
+	if body != "" {
+		reqBody = bytes.NewBufferString(body)
+	} else {
+		reqBody = &bytes.Buffer{}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.APIToken)
+	}
+	return req, nil
+}
+
+// applyJcasc POSTs yaml to Jenkins' `/configuration-as-code/apply` endpoint.
+func (c *jenkinsClient) applyJcasc(ctx context.Context, yaml string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, c.BaseURL+"/configuration-as-code/apply", yaml)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/yaml")
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("jenkins returned HTTP %d applying jcasc configuration", response.StatusCode)
+	}
+	return nil
+}
+
+// convertJcascToXML converts a job's JCasC YAML fragment into config.xml via
+// Jenkins' JCasC export API, so that jenkins_job can accept jcasc_template
+// as an alternative to xml_template while still going through the existing
+// job create/update path underneath.
+func (c *jenkinsClient) convertJcascToXML(ctx context.Context, yaml string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.BaseURL+"/configuration-as-code/export-xml", yaml)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/yaml")
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return "", fmt.Errorf("jenkins returned HTTP %d converting jcasc fragment to config.xml", response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// getJobConfigXML fetches the current config.xml for jobName.
+func (c *jenkinsClient) getJobConfigXML(ctx context.Context, jobName string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("%s/job/%s/config.xml", c.BaseURL, jobName), "")
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", ErrJobNotFound
+	}
+	if response.StatusCode >= 300 {
+		return "", fmt.Errorf("jenkins returned HTTP %d fetching job/%s/config.xml", response.StatusCode, jobName)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// createJob creates a new job named jobName from xml.
+func (c *jenkinsClient) createJob(ctx context.Context, jobName string, xml string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf("%s/createItem?name=%s", c.BaseURL, jobName), xml)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("jenkins returned HTTP %d creating job %q", response.StatusCode, jobName)
+	}
+	return nil
+}
+
+// updateJob replaces the config.xml of the existing job jobName with xml.
+func (c *jenkinsClient) updateJob(ctx context.Context, jobName string, xml string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf("%s/job/%s/config.xml", c.BaseURL, jobName), xml)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("jenkins returned HTTP %d updating job %q", response.StatusCode, jobName)
+	}
+	return nil
+}
+
+// deleteJob deletes the job jobName.
+func (c *jenkinsClient) deleteJob(ctx context.Context, jobName string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf("%s/job/%s/doDelete", c.BaseURL, jobName), "")
+	if err != nil {
+		return err
+	}
+
+	response, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("jenkins returned HTTP %d deleting job %q", response.StatusCode, jobName)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+)
+
+// canonicalizeXMLOptions configures canonicalizeXML's handling of
+// server-managed subtrees that would otherwise register as permanent
+// drift, as configured by jenkins_job's `ignore_xpath` attribute.
+type canonicalizeXMLOptions struct {
+	// IgnoreElements lists element local names (e.g. "hudson.scm.SCM") whose
+	// entire subtree is dropped before hashing/diffing.
+	IgnoreElements []string
+}
+
+// defaultDriftIgnoreElements matches config.xml subtrees Jenkins plugins
+// commonly rewrite on save (branch indexing metadata and branch sources)
+// that would otherwise look like permanent drift.
+var defaultDriftIgnoreElements = []string{
+	"jenkins.branch.BranchIndexing",
+	"jenkins.branch.BranchSource",
+}
+
+// canonicalizeXML normalizes data for comparison: elements are re-emitted
+// with their attributes sorted by name, insignificant whitespace between
+// tags is stripped, and any element whose local name appears in
+// opts.IgnoreElements (plus defaultDriftIgnoreElements) is omitted
+// entirely. The result is deterministic for semantically-equivalent XML
+// documents that differ only in attribute order or formatting.
+func canonicalizeXML(data string, opts canonicalizeXMLOptions) (string, error) {
+	ignore := map[string]bool{}
+	for _, name := range defaultDriftIgnoreElements {
+		ignore[name] = true
+	}
+	for _, name := range opts.IgnoreElements {
+		ignore[name] = true
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(data)))
+	var out bytes.Buffer
+	skipDepth := 0
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", fmt.Errorf("error canonicalizing xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if ignore[t.Name.Local] {
+				skipDepth = 1
+				continue
+			}
+			writeCanonicalStartElement(&out, t)
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			fmt.Fprintf(&out, "</%s>\n", t.Name.Local)
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			trimmed := bytes.TrimSpace(t)
+			if len(trimmed) > 0 {
+				out.Write(trimmed)
+			}
+		}
+	}
+
+	return out.String(), nil
+}
+
+func writeCanonicalStartElement(out *bytes.Buffer, t xml.StartElement) {
+	attrs := make([]xml.Attr, len(t.Attr))
+	copy(attrs, t.Attr)
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	fmt.Fprintf(out, "<%s", t.Name.Local)
+	for _, attr := range attrs {
+		fmt.Fprintf(out, " %s=%q", attr.Name.Local, attr.Value)
+	}
+	out.WriteString(">")
+}
+
+// DiffXML returns a human-readable, line-oriented diff between the
+// canonicalized forms of two config.xml documents, for surfacing in the
+// plan output when drift is detected. Lines present only in `want` are
+// prefixed with "+", lines present only in `have` with "-"; this is a
+// simple line-set comparison rather than a minimal-edit-distance diff.
+// canonicalizeXML ends a line after every closing tag, so a line holds an
+// element's closing tag together with any opening tags and text written
+// before it.
+func DiffXML(have, want string, opts canonicalizeXMLOptions) (string, error) {
+	canonicalHave, err := canonicalizeXML(have, opts)
+	if err != nil {
+		return "", err
+	}
+	canonicalWant, err := canonicalizeXML(want, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if canonicalHave == canonicalWant {
+		return "", nil
+	}
+
+	haveLines := splitNonEmptyLines(canonicalHave)
+	wantLines := splitNonEmptyLines(canonicalWant)
+	haveSet := map[string]int{}
+	for _, l := range haveLines {
+		haveSet[l]++
+	}
+	wantSet := map[string]int{}
+	for _, l := range wantLines {
+		wantSet[l]++
+	}
+
+	var diff bytes.Buffer
+	for _, l := range haveLines {
+		if wantSet[l] > 0 {
+			wantSet[l]--
+			continue
+		}
+		fmt.Fprintf(&diff, "- %s\n", l)
+	}
+	for _, l := range wantLines {
+		if haveSet[l] > 0 {
+			haveSet[l]--
+			continue
+		}
+		fmt.Fprintf(&diff, "+ %s\n", l)
+	}
+
+	return diff.String(), nil
+}
+
+func splitNonEmptyLines(s string) []string {
+	var lines []string
+	for _, l := range bytes.Split([]byte(s), []byte("\n")) {
+		if len(bytes.TrimSpace(l)) > 0 {
+			lines = append(lines, string(l))
+		}
+	}
+	return lines
+}
+
+// RemoteHash fetches job/<name>/config.xml from the running Jenkins
+// instance, canonicalizes it per opts, and returns both the raw config.xml
+// and its hash, for comparison against the canonicalized hash of the
+// last-applied bound template. Used by jenkins_job's Read to detect drift
+// introduced by edits made directly in the Jenkins UI; the raw XML is
+// returned alongside the hash so Read can build a DiffXML without a second
+// round-trip to Jenkins. Returns ErrJobNotFound, unwrapped, if the job no
+// longer exists.
+func RemoteHash(ctx context.Context, client *jenkinsClient, jobName string, opts canonicalizeXMLOptions) (hash string, rawXML string, err error) {
+	rawXML, err = client.getJobConfigXML(ctx, jobName)
+	if err != nil {
+		if !errors.Is(err, ErrJobNotFound) {
+			log.Printf("[ERROR] jenkins::drift - error fetching remote config.xml for %q: %v", jobName, err)
+		}
+		return "", "", err
+	}
+
+	canonical, err := canonicalizeXML(rawXML, opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	hash, err = computeHash("sha256", canonical)
+	if err != nil {
+		return "", "", err
+	}
+	return hash, rawXML, nil
+}
+
+// driftIgnoreXPathPattern extracts the element local name from the simple
+// `//parent/child` xpath forms accepted by jenkins_job's `ignore_xpath`.
+// Full XPath isn't supported; only "ignore this element and everything
+// under it" is, matching how defaultDriftIgnoreElements is expressed.
+var driftIgnoreXPathPattern = regexp.MustCompile(`([^/]+)$`)
+
+// ignoreElementsFromXPaths converts `ignore_xpath` entries like
+// `//scm/branches` into the element-name form canonicalizeXML expects.
+func ignoreElementsFromXPaths(xpaths []string) []string {
+	elements := make([]string, 0, len(xpaths))
+	for _, xpath := range xpaths {
+		if m := driftIgnoreXPathPattern.FindStringSubmatch(xpath); m != nil {
+			elements = append(elements, m[1])
+		}
+	}
+	return elements
+}
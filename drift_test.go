@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeXMLSortsAttributesAndStripsWhitespace(t *testing.T) {
+	a := `<project>
+		<disabled>false</disabled>
+		<scm class="hudson.scm.NullSCM" b="2" a="1"></scm>
+	</project>`
+	b := `<project><disabled>false</disabled><scm a="1" b="2" class="hudson.scm.NullSCM"/></project>`
+
+	canonicalA, err := canonicalizeXML(a, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	canonicalB, err := canonicalizeXML(b, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if canonicalA != canonicalB {
+		t.Fatalf("expected semantically equivalent documents to canonicalize identically:\na: %q\nb: %q", canonicalA, canonicalB)
+	}
+}
+
+func TestCanonicalizeXMLDropsIgnoredElements(t *testing.T) {
+	data := `<project>
+		<disabled>false</disabled>
+		<properties>
+			<jenkins.branch.BranchIndexing>
+				<lastIndex>12</lastIndex>
+			</jenkins.branch.BranchIndexing>
+		</properties>
+	</project>`
+
+	canonical, err := canonicalizeXML(data, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(canonical, "lastIndex") {
+		t.Fatalf("expected default-ignored BranchIndexing subtree to be dropped, got %q", canonical)
+	}
+
+	customIgnored := `<project><custom.Volatile><value>1</value></custom.Volatile></project>`
+	canonical, err = canonicalizeXML(customIgnored, canonicalizeXMLOptions{IgnoreElements: []string{"custom.Volatile"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(canonical, "value") {
+		t.Fatalf("expected user-specified ignore element to be dropped, got %q", canonical)
+	}
+}
+
+func TestDiffXMLNoDiffWhenEquivalent(t *testing.T) {
+	a := `<project a="1" b="2"></project>`
+	b := `<project b="2" a="1"/>`
+
+	diff, err := DiffXML(a, b, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != "" {
+		t.Fatalf("expected no diff for semantically equivalent xml, got %q", diff)
+	}
+}
+
+func TestDiffXMLReportsChangedElement(t *testing.T) {
+	have := `<project><disabled>false</disabled></project>`
+	want := `<project><disabled>true</disabled></project>`
+
+	diff, err := DiffXML(have, want, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff, "-") || !strings.Contains(diff, "<disabled>false</disabled>") {
+		t.Fatalf("expected diff to call out the removed value, got %q", diff)
+	}
+	if !strings.Contains(diff, "+") || !strings.Contains(diff, "<disabled>true</disabled>") {
+		t.Fatalf("expected diff to call out the added value, got %q", diff)
+	}
+}
+
+func TestIgnoreElementsFromXPaths(t *testing.T) {
+	got := ignoreElementsFromXPaths([]string{"//scm/branches", "topLevel"})
+	want := []string{"branches", "topLevel"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// templateFuncMap returns the Sprig-style helper functions made available
+// inside config.xml templates, on top of the Go standard library's
+// text/template builtins. `include` is deliberately not part of this map:
+// it needs a reference to the template being executed and is added
+// per-invocation by the caller.
+func templateFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"default":   templateFuncDefault,
+		"quote":     templateFuncQuote,
+		"xmlEscape": templateFuncXMLEscape,
+		"indent":    templateFuncIndent,
+		"b64enc":    templateFuncB64Enc,
+		"required":  templateFuncRequired,
+	}
+}
+
+// templateFuncDefault returns val unless it is empty (an empty string, nil,
+// false, or an empty slice/map), in which case it returns def. Numbers are
+// never treated as empty, so a zero number is returned as is.
+func templateFuncDefault(def interface{}, val interface{}) interface{} {
+	if templateValueIsEmpty(val) {
+		return def
+	}
+	return val
+}
+
+func templateValueIsEmpty(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]string:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// templateFuncQuote renders val as a double-quoted, backslash-escaped
+// string literal, matching Sprig's `quote`. This is meant for quoting YAML
+// scalars (e.g. in a jcasc_template); it is not XML-safe, since XML has no
+// backslash-escape syntax. Use xmlEscape to embed untrusted text in a
+// config.xml attribute or element body instead.
+func templateFuncQuote(val interface{}) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%v", val))
+}
+
+var xmlEscapeReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+// templateFuncXMLEscape escapes the five reserved XML characters in val.
+func templateFuncXMLEscape(val interface{}) string {
+	return xmlEscapeReplacer.Replace(fmt.Sprintf("%v", val))
+}
+
+// templateFuncIndent prefixes every line of val with n spaces.
+func templateFuncIndent(n int, val interface{}) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(fmt.Sprintf("%v", val), "\n")
+	for i, line := range lines {
+		lines[i] = pad + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// templateFuncB64Enc returns val, base64-encoded.
+func templateFuncB64Enc(val interface{}) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%v", val)))
+}
+
+// templateFuncRequired fails template execution with msg if val is empty,
+// letting authors demand a `.Values` key be set rather than silently
+// rendering a blank config.xml.
+func templateFuncRequired(msg string, val interface{}) (interface{}, error) {
+	if templateValueIsEmpty(val) {
+		return nil, fmt.Errorf(msg)
+	}
+	return val, nil
+}
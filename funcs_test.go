@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestTemplateFuncDefaultScalars(t *testing.T) {
+	cases := []struct {
+		name string
+		def  interface{}
+		val  interface{}
+		want interface{}
+	}{
+		{"empty string falls back", "fallback", "", "fallback"},
+		{"non-empty string kept", "fallback", "set", "set"},
+		{"nil falls back", "fallback", nil, "fallback"},
+		{"false bool falls back", true, false, true},
+		{"true bool kept", true, true, true},
+		{"zero number kept (not treated as empty)", 1, 0, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := templateFuncDefault(tc.def, tc.val)
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncDefaultEmptySliceFallsBack(t *testing.T) {
+	got := templateFuncDefault([]string{"d"}, []string{})
+	gotSlice, ok := got.([]string)
+	if !ok || len(gotSlice) != 1 || gotSlice[0] != "d" {
+		t.Fatalf("got %v, want the default slice", got)
+	}
+}
+
+func TestTemplateFuncDefaultEmptyMapFallsBack(t *testing.T) {
+	got := templateFuncDefault(map[string]string{"k": "d"}, map[string]string{})
+	gotMap, ok := got.(map[string]string)
+	if !ok || gotMap["k"] != "d" {
+		t.Fatalf("got %v, want the default map", got)
+	}
+}
+
+func TestTemplateFuncQuote(t *testing.T) {
+	got := templateFuncQuote(`he said "hi"`)
+	want := `"he said \"hi\""`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncXMLEscape(t *testing.T) {
+	got := templateFuncXMLEscape(`Build & deploy <prod> job's "release"`)
+	want := "Build &amp; deploy &lt;prod&gt; job&apos;s &quot;release&quot;"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncIndent(t *testing.T) {
+	got := templateFuncIndent(2, "a\nb")
+	want := "  a\n  b"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncB64Enc(t *testing.T) {
+	got := templateFuncB64Enc("hello")
+	want := "aGVsbG8="
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncRequired(t *testing.T) {
+	if _, err := templateFuncRequired("must be set", ""); err == nil {
+		t.Fatalf("expected an error for an empty value")
+	}
+	got, err := templateFuncRequired("must be set", "present")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "present" {
+		t.Fatalf("got %v, want %v", got, "present")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// computeHash hashes data using algo ("md5" or "sha256", defaulting to
+// "md5" when algo is empty, for backward compatibility with addresses
+// that predate the `md5:`/`sha256:` prefix), shared between
+// ConfigXMLTemplate and ConfigYAMLTemplate.
+func computeHash(algo string, data string) (string, error) {
+	if algo == "sha256" {
+		hash := sha256.Sum256([]byte(data))
+		return strings.ToLower(hex.EncodeToString(hash[:])), nil
+	}
+
+	hash := md5.Sum([]byte(data))
+	return strings.ToLower(hex.EncodeToString(hash[:])), nil
+}
+
+// inferPinnedHashAlgo determines the hash algorithm for an `@[algo:]hash`
+// integrity pin. An explicit prefixAlgo (from `md5:`/`sha256:`) always
+// wins; otherwise the algorithm is inferred from the hex digest length,
+// since a bare 64-character pin cannot be an MD5 sum. This keeps a bare,
+// unprefixed sha256 pin from being silently (and permanently) verified
+// against the wrong algorithm.
+func inferPinnedHashAlgo(prefixAlgo string, hash string) (string, error) {
+	if prefixAlgo != "" {
+		return prefixAlgo, nil
+	}
+	switch len(hash) {
+	case 32:
+		return "md5", nil
+	case 64:
+		return "sha256", nil
+	default:
+		return "", fmt.Errorf("unrecognized integrity pin length %d for %q", len(hash), hash)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestInferPinnedHashAlgo(t *testing.T) {
+	cases := []struct {
+		name       string
+		prefixAlgo string
+		hash       string
+		want       string
+		wantErr    bool
+	}{
+		{"explicit md5 prefix wins", "md5", "deadbeef", "md5", false},
+		{"explicit sha256 prefix wins", "sha256", "deadbeef", "sha256", false},
+		{"bare 32-char digest infers md5", "", "d41d8cd98f00b204e9800998ecf8427e", "md5", false},
+		{"bare 64-char digest infers sha256", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "sha256", false},
+		{"unrecognized length errors", "", "abcd", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := inferPinnedHashAlgo(tc.prefixAlgo, tc.hash)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got algo %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got algo %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestComputeHash(t *testing.T) {
+	cases := []struct {
+		algo string
+		data string
+		want string
+	}{
+		{"md5", "", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"sha256", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"", "", "d41d8cd98f00b204e9800998ecf8427e"},
+	}
+
+	for _, tc := range cases {
+		got, err := computeHash(tc.algo, tc.data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("computeHash(%q, %q) = %q, want %q", tc.algo, tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestNewConfigXMLTemplateBareSha256PinVerifies(t *testing.T) {
+	f, err := ioutil.TempFile("", "config-*.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	data := "<project/>"
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.Close()
+
+	hash, err := computeHash("sha256", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tpl, err := NewConfigXMLTemplate("file://" + f.Name() + "@" + hash)
+	if err != nil {
+		t.Fatalf("unexpected error verifying a bare sha256 pin: %v", err)
+	}
+	if tpl.hashAlgo != "sha256" {
+		t.Fatalf("got hashAlgo %q, want sha256", tpl.hashAlgo)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TemplateLoader fetches the raw bytes of a template from a URI.
+// prevETag, if non-empty, is the revision token from a previous Load of
+// the same uri; a loader that can cheaply tell that nothing changed
+// should return notModified=true (with data left nil) instead of
+// re-fetching. The returned etag, if any, is an opaque revision token
+// (e.g. an HTTP ETag) the caller will pass back as prevETag on the next
+// Load; loaders with no notion of a revision may always return "" and
+// notModified=false.
+type TemplateLoader interface {
+	Load(ctx context.Context, uri string, prevETag string) (data []byte, etag string, notModified bool, err error)
+}
+
+var (
+	templateLoaderRegistryMu sync.RWMutex
+	templateLoaderRegistry   = map[string]TemplateLoader{}
+)
+
+// RegisterTemplateLoader registers a TemplateLoader for the given URI
+// scheme (e.g. "http", "s3"), overriding any loader previously registered
+// for that scheme.
+func RegisterTemplateLoader(scheme string, loader TemplateLoader) {
+	templateLoaderRegistryMu.Lock()
+	defer templateLoaderRegistryMu.Unlock()
+	templateLoaderRegistry[scheme] = loader
+}
+
+// templateLoader returns the TemplateLoader registered for scheme, if any.
+func templateLoader(scheme string) (TemplateLoader, bool) {
+	templateLoaderRegistryMu.RLock()
+	defer templateLoaderRegistryMu.RUnlock()
+	loader, ok := templateLoaderRegistry[scheme]
+	return loader, ok
+}
+
+func init() {
+	httpLoader := newHTTPTemplateLoader(30 * time.Second)
+	RegisterTemplateLoader("http", httpLoader)
+	RegisterTemplateLoader("https", httpLoader)
+	RegisterTemplateLoader("file", fileTemplateLoader{})
+
+	// git, s3 and gs are recognized schemes but are not yet implemented;
+	// registering stub loaders gives callers a clear error instead of the
+	// generic "no loader registered" one.
+	for _, scheme := range []string{"git", "s3", "gs"} {
+		RegisterTemplateLoader(scheme, unimplementedTemplateLoader{scheme: scheme})
+	}
+}
+
+// httpTemplateLoader fetches templates over HTTP(S), retrying 5xx
+// responses with exponential backoff.
+//
+// NOTE: there is no provider-level `template_auth` block in this snapshot
+// to configure per-prefix HTTP basic/bearer credentials (no provider.go
+// exists here at all), so that wiring was removed rather than shipped
+// unreachable; add it back alongside whatever registers resources with
+// schema.Provider.
+type httpTemplateLoader struct {
+	client     *http.Client
+	maxRetries int
+}
+
+func newHTTPTemplateLoader(timeout time.Duration) *httpTemplateLoader {
+	return &httpTemplateLoader{
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: 3,
+	}
+}
+
+func (l *httpTemplateLoader) Load(ctx context.Context, uri string, prevETag string) ([]byte, string, bool, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= l.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			jitter := time.Duration(rand.Intn(250)) * time.Millisecond
+			log.Printf("[DEBUG] jenkins::xml - retrying template fetch %q (attempt %d) after %v", uri, attempt+1, backoff+jitter)
+			select {
+			case <-time.After(backoff + jitter):
+			case <-ctx.Done():
+				return nil, "", false, ctx.Err()
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if prevETag != "" {
+			req.Header.Set("If-None-Match", prevETag)
+		}
+
+		response, err := l.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if response.StatusCode >= 500 {
+			response.Body.Close()
+			lastErr = fmt.Errorf("server error fetching %q: HTTP %d", uri, response.StatusCode)
+			continue
+		}
+
+		defer response.Body.Close()
+
+		if response.StatusCode == http.StatusNotModified {
+			log.Printf("[DEBUG] jenkins::xml - template %q not modified (etag %q)", uri, prevETag)
+			return nil, prevETag, true, nil
+		}
+
+		if response.StatusCode >= 400 {
+			return nil, "", false, fmt.Errorf("error fetching %q: HTTP %d", uri, response.StatusCode)
+		}
+
+		data, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return data, response.Header.Get("ETag"), false, nil
+	}
+
+	return nil, "", false, fmt.Errorf("giving up fetching %q after %d attempts: %v", uri, l.maxRetries+1, lastErr)
+}
+
+// fileTemplateLoader reads templates from the local filesystem. uri is
+// expected in `file://` form. Its revision token is the file's modtime and
+// size, which is cheap to stat without reading the file body.
+type fileTemplateLoader struct{}
+
+func (fileTemplateLoader) Load(ctx context.Context, uri string, prevETag string) ([]byte, string, bool, error) {
+	from := strings.Replace(uri, "file://", "", 1)
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return nil, "", false, err
+	}
+	etag := fileETag(info)
+	if prevETag != "" && prevETag == etag {
+		return nil, etag, true, nil
+	}
+
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return data, etag, false, nil
+}
+
+func fileETag(info os.FileInfo) string {
+	return strconv.FormatInt(info.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36)
+}
+
+// unimplementedTemplateLoader registers a recognized scheme so that
+// misconfiguration is reported as "not yet implemented" instead of "unknown
+// scheme".
+type unimplementedTemplateLoader struct {
+	scheme string
+}
+
+func (l unimplementedTemplateLoader) Load(ctx context.Context, uri string, prevETag string) ([]byte, string, bool, error) {
+	return nil, "", false, fmt.Errorf("template loader for scheme %q is not yet implemented", l.scheme)
+}
+
+// templateCacheEntry holds a cached template fetch, keyed by source URI.
+type templateCacheEntry struct {
+	data []byte
+	etag string
+}
+
+// templateLRUCache is a small in-process, size-bounded LRU cache of loaded
+// template bytes keyed by URI. It does not invalidate on its own: callers
+// are expected to pass the cached entry's etag back to TemplateLoader.Load
+// as prevETag on every fetch, so that a loader can report notModified and
+// the stale cache entry can be kept (and its recency refreshed) rather
+// than served forever once present.
+type templateLRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    []string
+	entries  map[string]templateCacheEntry
+}
+
+func newTemplateLRUCache(capacity int) *templateLRUCache {
+	return &templateLRUCache{
+		capacity: capacity,
+		entries:  map[string]templateCacheEntry{},
+	}
+}
+
+func (c *templateLRUCache) get(key string) (templateCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok {
+		c.touch(key)
+	}
+	return entry, ok
+}
+
+func (c *templateLRUCache) put(key string, entry templateCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.capacity {
+		c.evictOldest()
+	}
+	c.entries[key] = entry
+	c.touch(key)
+}
+
+// touch moves key to the back of the recency order. Callers must hold c.mu.
+func (c *templateLRUCache) touch(key string) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, key)
+}
+
+// evictOldest removes the least-recently-used entry. Callers must hold c.mu.
+func (c *templateLRUCache) evictOldest() {
+	if len(c.order) == 0 {
+		return
+	}
+	oldest := c.order[0]
+	c.order = c.order[1:]
+	delete(c.entries, oldest)
+}
+
+// defaultTemplateCache is the process-wide cache used by
+// NewConfigXMLTemplateContext and NewConfigYAMLTemplateContext.
+var defaultTemplateCache = newTemplateLRUCache(64)
+
+// loadTemplateViaCache fetches source through loader, revalidating against
+// defaultTemplateCache's entry (if any) by etag rather than serving it
+// unconditionally. logPrefix is used for the jenkins::xml / jenkins::jcasc
+// debug logging convention.
+func loadTemplateViaCache(ctx context.Context, loader TemplateLoader, source string, logPrefix string) ([]byte, error) {
+	cached, hasCached := defaultTemplateCache.get(source)
+
+	prevETag := ""
+	if hasCached {
+		prevETag = cached.etag
+	}
+
+	log.Printf("[DEBUG] %s - checking template for updates: %q", logPrefix, source)
+	fetched, etag, notModified, err := loader.Load(ctx, source, prevETag)
+	if err != nil {
+		log.Printf("[ERROR] %s - error retrieving template %q: %v", logPrefix, source, err)
+		return nil, err
+	}
+
+	if notModified && hasCached {
+		log.Printf("[DEBUG] %s - template unchanged, serving from cache: %q", logPrefix, source)
+		return cached.data, nil
+	}
+
+	if notModified && !hasCached {
+		// the loader believes nothing changed but we have no prior cache
+		// entry (e.g. cache eviction); fetched will be nil, so there is
+		// nothing sensible to return.
+		return nil, fmt.Errorf("template loader reported no change for %q but no cached copy is available", source)
+	}
+
+	defaultTemplateCache.put(source, templateCacheEntry{data: fetched, etag: etag})
+	return fetched, nil
+}
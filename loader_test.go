@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestTemplateLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTemplateLRUCache(2)
+	c.put("a", templateCacheEntry{data: []byte("a")})
+	c.put("b", templateCacheEntry{data: []byte("b")})
+	c.put("c", templateCacheEntry{data: []byte("c")})
+
+	if _, ok := c.get("a"); ok {
+		t.Fatalf("expected %q to have been evicted as least-recently-used", "a")
+	}
+	if _, ok := c.get("b"); !ok {
+		t.Fatalf("expected %q to still be cached", "b")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Fatalf("expected %q to still be cached", "c")
+	}
+}
+
+func TestTemplateLRUCacheGetRefreshesRecency(t *testing.T) {
+	c := newTemplateLRUCache(2)
+	c.put("a", templateCacheEntry{data: []byte("a")})
+	c.put("b", templateCacheEntry{data: []byte("b")})
+
+	// touching "a" should make "b" the next eviction candidate instead.
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("expected %q to be cached", "a")
+	}
+	c.put("c", templateCacheEntry{data: []byte("c")})
+
+	if _, ok := c.get("b"); ok {
+		t.Fatalf("expected %q to have been evicted after %q was touched", "b", "a")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("expected %q to survive eviction after being touched", "a")
+	}
+}
+
+type stubTemplateLoader struct {
+	data        []byte
+	etag        string
+	notModified bool
+	err         error
+}
+
+func (l stubTemplateLoader) Load(ctx context.Context, uri string, prevETag string) ([]byte, string, bool, error) {
+	return l.data, l.etag, l.notModified, l.err
+}
+
+func TestLoadTemplateViaCacheServesCachedDataOnNotModified(t *testing.T) {
+	defaultTemplateCache = newTemplateLRUCache(64)
+	defaultTemplateCache.put("stub://x", templateCacheEntry{data: []byte("cached"), etag: "v1"})
+
+	loader := stubTemplateLoader{notModified: true}
+	data, err := loadTemplateViaCache(context.Background(), loader, "stub://x", "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "cached" {
+		t.Fatalf("got %q, want cached data to be served", data)
+	}
+}
+
+func TestLoadTemplateViaCacheNotModifiedWithoutCacheEntryErrors(t *testing.T) {
+	defaultTemplateCache = newTemplateLRUCache(64)
+
+	loader := stubTemplateLoader{notModified: true}
+	_, err := loadTemplateViaCache(context.Background(), loader, "stub://never-cached", "test")
+	if err == nil {
+		t.Fatalf("expected an error when the loader reports notModified with no prior cache entry")
+	}
+}
+
+func TestFileTemplateLoaderRevalidatesByModTimeAndSize(t *testing.T) {
+	f, err := ioutil.TempFile("", "loader-*.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("<project/>"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.Close()
+
+	loader := fileTemplateLoader{}
+	uri := "file://" + f.Name()
+
+	data, etag, notModified, err := loader.Load(context.Background(), uri, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notModified {
+		t.Fatalf("expected a fresh fetch with no prevETag to not report notModified")
+	}
+	if string(data) != "<project/>" {
+		t.Fatalf("got %q, want file contents", data)
+	}
+
+	_, _, notModified, err = loader.Load(context.Background(), uri, etag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !notModified {
+		t.Fatalf("expected an unchanged file to report notModified when the prior etag is passed back")
+	}
+}
+
+func TestHTTPTemplateLoaderRetriesOn5xxThenSucceeds(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("ETag", "v2")
+		w.Write([]byte("<project/>"))
+	}))
+	defer server.Close()
+
+	loader := &httpTemplateLoader{client: server.Client(), maxRetries: 1}
+	data, etag, notModified, err := loader.Load(context.Background(), server.URL, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notModified {
+		t.Fatalf("expected a successful retry to not report notModified")
+	}
+	if string(data) != "<project/>" || etag != "v2" {
+		t.Fatalf("got data %q etag %q, want the response served on retry", data, etag)
+	}
+	if attempts != 2 {
+		t.Fatalf("got %d attempts, want 2 (one failure, one retry)", attempts)
+	}
+}
+
+func TestHTTPTemplateLoaderReturns304AsNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "v1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "v1")
+		w.Write([]byte("<project/>"))
+	}))
+	defer server.Close()
+
+	loader := &httpTemplateLoader{client: server.Client(), maxRetries: 1}
+	_, etag, _, err := loader.Load(context.Background(), server.URL, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _, notModified, err := loader.Load(context.Background(), server.URL, etag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !notModified {
+		t.Fatalf("expected a matching If-None-Match to report notModified")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceJenkinsJcascConfig manages Jenkins' global Configuration-as-Code
+// (JCasC) YAML via the `/configuration-as-code/apply` endpoint, as an
+// alternative to hand-assembling config.xml for global configuration.
+//
+// See resource_job.go for `jcasc_template` as an alternative to
+// `xml_template` on jenkins_job.
+func resourceJenkinsJcascConfig() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJenkinsJcascConfigCreateUpdate,
+		ReadContext:   resourceJenkinsJcascConfigRead,
+		UpdateContext: resourceJenkinsJcascConfigCreateUpdate,
+		DeleteContext: resourceJenkinsJcascConfigDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "A unique name identifying this JCasC configuration within Terraform state.",
+			},
+			"jcasc_template": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Address or inline/embedded JCasC YAML template, in the same `source[@hash]` form accepted by `xml_template`.",
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary key/value pairs exposed to the template as `.Values`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceJenkinsJcascConfigCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	tpl, err := NewConfigYAMLTemplateContext(ctx, d.Get("jcasc_template").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	yaml, diags := tpl.BindToContext(ctx, d)
+	if diags.HasError() {
+		return diags
+	}
+
+	client, ok := meta.(*jenkinsClient)
+	if !ok {
+		return diag.Errorf("jenkins::jcasc - provider is not configured with a Jenkins client")
+	}
+
+	if err := client.applyJcasc(ctx, yaml); err != nil {
+		return diag.FromErr(fmt.Errorf("error applying jcasc configuration: %w", err))
+	}
+
+	d.SetId(d.Get("name").(string))
+	return nil
+}
+
+func resourceJenkinsJcascConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Jenkins' JCasC export endpoint returns the effective configuration,
+	// not the YAML that produced it, so there is nothing meaningful to diff
+	// against the bound template here; presence of the id is all Read
+	// confirms.
+	if d.Id() == "" {
+		return nil
+	}
+	return nil
+}
+
+func resourceJenkinsJcascConfigDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[WARN] jenkins::jcasc - JCasC has no delete semantics server-side; removing %q from state only", d.Id())
+	d.SetId("")
+	return nil
+}
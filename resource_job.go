@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceJenkinsJob manages a Jenkins job's config.xml, either rendered
+// directly from an `xml_template` or converted from a `jcasc_template`
+// fragment via Jenkins' JCasC export API. When both are set, `xml_template`
+// takes precedence, matching how `values` already layers onto whichever
+// template is in use.
+func resourceJenkinsJob() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJenkinsJobCreate,
+		ReadContext:   resourceJenkinsJobRead,
+		UpdateContext: resourceJenkinsJobUpdate,
+		DeleteContext: resourceJenkinsJobDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The job's name in Jenkins.",
+			},
+			"xml_template": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Address or inline/embedded config.xml template, in `source[@hash]` form. Takes precedence over `jcasc_template` when both are set.",
+			},
+			"jcasc_template": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Address or inline/embedded JCasC YAML template for this job, converted to config.xml via Jenkins' JCasC export API. Ignored when `xml_template` is set.",
+			},
+			"display_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.DisplayName`.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.Description`.",
+			},
+			"trigger_remotely_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.TriggerRemotelyToken`.",
+			},
+			"disabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.Disabled`.",
+			},
+			"master_merge_triggering": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.MasterMergeTriggering`.",
+			},
+			"permissions": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Comma-separated permission grants, exposed to `xml_template` (not to `jcasc_template`) as `.Permissions`.",
+			},
+			"configuration": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.Configuration`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"pr_triggering_ghpr": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.PrTriggeringGhpr`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"pr_triggering_gh_integration": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.PrTriggeringGhIntegration`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"parameter": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Job parameter definitions, exposed to `xml_template` (not to `jcasc_template`) as `.Parameters`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"default": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"branch_push_triggering": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.BranchPushTriggering`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"jenkinsfile": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Exposed to `xml_template` (not to `jcasc_template`) as `.Jenkinsfile`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary key/value pairs exposed to the template as `.Values`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"ignore_xpath": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Simple `//parent/child`-style paths identifying config.xml subtrees (e.g. those rewritten by plugins on save) to exclude from drift detection, on top of the built-in defaults.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"rendered_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 hash of the canonicalized config.xml last applied to Jenkins, used to detect drift on Read. A Read that detects drift replaces it with the hash of the job's config.xml on the server.",
+			},
+		},
+	}
+}
+
+func resourceJenkinsJobClient(meta interface{}) (*jenkinsClient, diag.Diagnostics) {
+	client, ok := meta.(*jenkinsClient)
+	if !ok {
+		return nil, diag.Errorf("jenkins::job - provider is not configured with a Jenkins client")
+	}
+	return client, nil
+}
+
+// resourceJenkinsJobDriftOptions builds the canonicalization options for
+// this resource's `ignore_xpath`.
+func resourceJenkinsJobDriftOptions(d *schema.ResourceData) canonicalizeXMLOptions {
+	raw := d.Get("ignore_xpath").([]interface{})
+	xpaths := make([]string, 0, len(raw))
+	for _, v := range raw {
+		xpaths = append(xpaths, v.(string))
+	}
+	return canonicalizeXMLOptions{IgnoreElements: ignoreElementsFromXPaths(xpaths)}
+}
+
+// resourceJenkinsJobRenderXML resolves the job's desired config.xml,
+// honoring `xml_template`'s precedence over `jcasc_template`.
+func resourceJenkinsJobRenderXML(ctx context.Context, d *schema.ResourceData, client *jenkinsClient) (string, diag.Diagnostics) {
+	if raw, ok := d.GetOk("xml_template"); ok {
+		tpl, err := NewConfigXMLTemplateContext(ctx, raw.(string))
+		if err != nil {
+			return "", diag.FromErr(err)
+		}
+		return tpl.BindToContext(ctx, d)
+	}
+
+	raw, ok := d.GetOk("jcasc_template")
+	if !ok {
+		return "", diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Either xml_template or jcasc_template must be set",
+			AttributePath: cty.GetAttrPath("xml_template"),
+		}}
+	}
+
+	tpl, err := NewConfigYAMLTemplateContext(ctx, raw.(string))
+	if err != nil {
+		return "", diag.FromErr(err)
+	}
+	yaml, diags := tpl.BindToContext(ctx, d)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	xml, err := client.convertJcascToXML(ctx, yaml)
+	if err != nil {
+		return "", diag.FromErr(fmt.Errorf("error converting jcasc_template to config.xml: %w", err))
+	}
+	return xml, nil
+}
+
+func resourceJenkinsJobCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := resourceJenkinsJobClient(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	name := d.Get("name").(string)
+	xml, diags := resourceJenkinsJobRenderXML(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := client.createJob(ctx, name, xml); err != nil {
+		return diag.FromErr(fmt.Errorf("error creating job %q: %w", name, err))
+	}
+
+	hash, err := resourceJenkinsJobSetRenderedHash(d, xml)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	log.Printf("[DEBUG] jenkins::job - created %q with rendered hash %s", name, hash)
+
+	d.SetId(name)
+	return nil
+}
+
+func resourceJenkinsJobUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := resourceJenkinsJobClient(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	name := d.Get("name").(string)
+	xml, diags := resourceJenkinsJobRenderXML(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := client.updateJob(ctx, name, xml); err != nil {
+		return diag.FromErr(fmt.Errorf("error updating job %q: %w", name, err))
+	}
+
+	if _, err := resourceJenkinsJobSetRenderedHash(d, xml); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceJenkinsJobSetRenderedHash(d *schema.ResourceData, xml string) (string, error) {
+	canonical, err := canonicalizeXML(xml, resourceJenkinsJobDriftOptions(d))
+	if err != nil {
+		return "", fmt.Errorf("error canonicalizing rendered config.xml: %w", err)
+	}
+	hash, err := computeHash("sha256", canonical)
+	if err != nil {
+		return "", err
+	}
+	if err := d.Set("rendered_hash", hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// resourceJenkinsJobRead compares the last-applied rendered_hash against the
+// job's actual config.xml on the Jenkins server, via RemoteHash, and surfaces
+// any out-of-band drift as a warning diagnostic carrying a human-readable
+// DiffXML. The state's rendered_hash is refreshed to the remote value so
+// Terraform's plan reflects what's actually on the server.
+func resourceJenkinsJobRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.Id() == "" {
+		return nil
+	}
+
+	client, diags := resourceJenkinsJobClient(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	name := d.Id()
+	opts := resourceJenkinsJobDriftOptions(d)
+
+	remoteHash, remoteXML, err := RemoteHash(ctx, client, name, opts)
+	if errors.Is(err, ErrJobNotFound) {
+		log.Printf("[WARN] jenkins::job - %q no longer exists, removing from state", name)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading job %q: %w", name, err))
+	}
+
+	recordedHash := d.Get("rendered_hash").(string)
+	if remoteHash == recordedHash {
+		return nil
+	}
+
+	desiredXML, diags := resourceJenkinsJobRenderXML(ctx, d, client)
+	if diags.HasError() {
+		return diags
+	}
+
+	diffText, err := DiffXML(remoteXML, desiredXML, opts)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error diffing job %q config.xml: %w", name, err))
+	}
+
+	if err := d.Set("rendered_hash", remoteHash); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if diffText == "" {
+		return nil
+	}
+
+	log.Printf("[WARN] jenkins::job - drift detected for %q:\n%s", name, diffText)
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Detected out-of-band changes to job %q", name),
+		Detail:   diffText,
+	}}
+}
+
+func resourceJenkinsJobDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := resourceJenkinsJobClient(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := client.deleteJob(ctx, d.Id()); err != nil {
+		return diag.FromErr(fmt.Errorf("error deleting job %q: %w", d.Id(), err))
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testJenkinsJobResourceData(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+	return schema.TestResourceDataRaw(t, resourceJenkinsJob().Schema, raw)
+}
+
+func TestResourceJenkinsJobReadRemovesStateWhenJobGone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &jenkinsClient{BaseURL: server.URL}
+	d := testJenkinsJobResourceData(t, map[string]interface{}{
+		"name":         "gone-job",
+		"xml_template": "<project/>",
+	})
+	d.SetId("gone-job")
+
+	diags := resourceJenkinsJobRead(context.Background(), d, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error diagnostics: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected id to be cleared when the job no longer exists, got %q", d.Id())
+	}
+}
+
+func TestResourceJenkinsJobRenderXMLLegacyAttributes(t *testing.T) {
+	const tmpl = `<project>
+	<displayName>{{ .DisplayName }}</displayName>
+	<disabled>{{ .Disabled }}</disabled>
+	{{- range $k, $v := .Configuration }}
+	<config key="{{ $k }}">{{ $v }}</config>
+	{{- end }}
+	{{- range .Parameters }}
+	<parameter name="{{ .Name }}" type="{{ .Type }}"/>
+	{{- end }}
+</project>`
+
+	d := testJenkinsJobResourceData(t, map[string]interface{}{
+		"name":         "job-with-legacy-fields",
+		"xml_template": tmpl,
+		"display_name": "Job With Legacy Fields",
+		"disabled":     true,
+		"configuration": map[string]interface{}{
+			"timeout": "30",
+		},
+		"parameter": []interface{}{
+			map[string]interface{}{
+				"name": "BRANCH",
+				"type": "string",
+			},
+		},
+	})
+
+	xml, diags := resourceJenkinsJobRenderXML(context.Background(), d, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected error diagnostics: %v", diags)
+	}
+
+	for _, want := range []string{
+		"<displayName>Job With Legacy Fields</displayName>",
+		"<disabled>true</disabled>",
+		`<config key="timeout">30</config>`,
+		`<parameter name="BRANCH" type="string"/>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Fatalf("expected rendered xml to contain %q, got %q", want, xml)
+		}
+	}
+}
+
+func TestResourceJenkinsJobReadNoDiagWhenHashMatches(t *testing.T) {
+	const xml = "<project><disabled>false</disabled></project>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(xml))
+	}))
+	defer server.Close()
+
+	canonical, err := canonicalizeXML(xml, canonicalizeXMLOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hash, err := computeHash("sha256", canonical)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &jenkinsClient{BaseURL: server.URL}
+	d := testJenkinsJobResourceData(t, map[string]interface{}{
+		"name":          "stable-job",
+		"xml_template":  "<project/>",
+		"rendered_hash": hash,
+	})
+	d.SetId("stable-job")
+
+	diags := resourceJenkinsJobRead(context.Background(), d, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error diagnostics: %v", diags)
+	}
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics when rendered_hash matches the remote job, got %v", diags)
+	}
+}
@@ -2,76 +2,106 @@ package main
 
 import (
 	"bytes"
-	"crypto/md5"
+	"context"
 	"fmt"
-	"html/template"
-	"io/ioutil"
 	"log"
-	"net/http"
 	"regexp"
 	"strings"
+	"text/template"
 
-	"encoding/hex"
-
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 // ConfigXMLTemplate represents a config.xml template as an object.
 type ConfigXMLTemplate struct {
-	source string
-	data   string
-	hash   string
+	source   string
+	data     string
+	hash     string
+	hashAlgo string
 }
 
 // NewConfigXMLTemplate creates a new ConfigXMLTemplate using the provided
-// address or inline/embedded data.
+// address or inline/embedded data. It is a convenience wrapper around
+// NewConfigXMLTemplateContext using context.Background() and the default
+// loader registry.
 func NewConfigXMLTemplate(input string) (*ConfigXMLTemplate, error) {
+	return NewConfigXMLTemplateContext(context.Background(), input)
+}
 
-	configuration := &ConfigXMLTemplate{}
-	var source string
+// NewConfigXMLTemplateContext creates a new ConfigXMLTemplate using the
+// provided address or inline/embedded data, dispatching to the
+// TemplateLoader registered for the address's scheme. If the address
+// carries an `@<algo>:<hash>` integrity pin, the loaded data is verified
+// against it before the template is accepted.
+func NewConfigXMLTemplateContext(ctx context.Context, input string) (*ConfigXMLTemplate, error) {
 
-	// extract data and hash, if the hash is there
-	re := regexp.MustCompile(`.*@[a-f0-9]{32}$`)
-	if re.MatchString(input) {
-		source = input[:len(input)-33]
-		configuration.hash = input[len(input)-32:]
-	} else {
-		source = input
-	}
+	configuration := &ConfigXMLTemplate{}
+	source := input
 
-	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-		log.Printf("[DEBUG] jenkins::xml - retrieving template from URL %q", source)
-		response, err := http.Get(source)
+	// extract the integrity pin, if there is one: either a bare hex digest
+	// (legacy; algorithm inferred from its length), or an explicit
+	// `md5:`/`sha256:` prefixed hash.
+	if m := pinnedHashPattern.FindStringSubmatch(input); m != nil {
+		hash := strings.ToLower(m[2])
+		algo, err := inferPinnedHashAlgo(m[1], hash)
 		if err != nil {
-			log.Printf("[ERROR] jenkins::xml - error connecting to HTTP server: %v", err)
 			return nil, err
 		}
-		defer response.Body.Close()
-		data, err := ioutil.ReadAll(response.Body)
+		source = input[:len(input)-len(m[0])]
+		configuration.hashAlgo = algo
+		configuration.hash = hash
+	}
+
+	scheme := "inline"
+	if idx := strings.Index(source, "://"); idx >= 0 {
+		scheme = source[:idx]
+	}
+
+	if scheme == "inline" {
+		log.Printf("[DEBUG] jenkins::xml - template is inline: %q", source)
+		configuration.source = ""
+		configuration.data = source
+		return configuration, nil
+	}
+
+	loader, ok := templateLoader(scheme)
+	if !ok {
+		err := fmt.Errorf("no template loader registered for scheme %q", scheme)
+		log.Printf("[ERROR] jenkins::xml - %v", err)
+		return nil, err
+	}
+
+	data, err := loadTemplateViaCache(ctx, loader, source, "jenkins::xml")
+	if err != nil {
+		return nil, err
+	}
+
+	configuration.source = source
+	configuration.data = string(data)
+
+	if configuration.hash != "" {
+		computed, err := configuration.ComputedHash()
 		if err != nil {
-			log.Printf("[ERROR] jenkins::xml - error reading HTTP server response: %v", err)
 			return nil, err
 		}
-		configuration.source = source
-		configuration.data = string(data)
-	} else if strings.HasPrefix(source, "file://") {
-		log.Printf("[DEBUG] jenkins::xml - retrieving template from filesystem: %q", source)
-		from := strings.Replace(source, "file://", "", 1)
-		data, err := ioutil.ReadFile(from)
-		if err != nil {
-			log.Printf("[ERROR] jenkins::xml - error reading from filesystem: %v", err)
+		if computed != configuration.hash {
+			err := fmt.Errorf("integrity check failed for %q: expected %s:%s, computed %s:%s",
+				source, configuration.hashAlgo, configuration.hash, configuration.hashAlgo, computed)
+			log.Printf("[ERROR] jenkins::xml - %v", err)
 			return nil, err
 		}
-		configuration.source = source
-		configuration.data = string(data)
-	} else {
-		log.Printf("[DEBUG] jenkins::xml - template is inline: %q", source)
-		configuration.source = ""
-		configuration.data = source
 	}
+
 	return configuration, nil
 }
 
+// pinnedHashPattern matches an optional `@<algo>:<hash>` integrity pin
+// suffix. The algo group is empty for the bare, unprefixed form, whose
+// algorithm inferPinnedHashAlgo infers from the digest length.
+var pinnedHashPattern = regexp.MustCompile(`@(?:(md5|sha256):)?([a-f0-9]{32}|[a-f0-9]{64})$`)
+
 func (c *ConfigXMLTemplate) GetTemplateID() (string, error) {
 	if c == nil {
 		log.Printf("[ERROR] jenkins::xml - invalid config.xml template object")
@@ -98,109 +128,126 @@ func (c *ConfigXMLTemplate) RecordedHash() (string, error) {
 	return c.hash, nil
 }
 
-// ComputedHash returns the SHA-256 hash of the current (unbound) template.
+// ComputedHash returns the hash of the current (unbound) template, using
+// whichever algorithm was pinned on the source address (`md5:` or
+// `sha256:`, or inferred from the length of a bare, unprefixed hash),
+// defaulting to MD5 for backward compatibility when the address carries no
+// hash.
 func (c *ConfigXMLTemplate) ComputedHash() (string, error) {
 	if c == nil {
 		log.Printf("[ERROR] jenkins::xml - invalid config.xml template object")
 		return "", fmt.Errorf("Invalid config.xml template object")
 	}
-
-	//hash := sha512.Sum512([]byte(c.template))
-	hash := md5.Sum([]byte(c.data))
-	return strings.ToLower(hex.EncodeToString(hash[:])), nil
+	return computeHash(c.hashAlgo, c.data)
 }
 
-// BindTo binds the current config.xml template to the given resource data.
-func (c *ConfigXMLTemplate) BindTo(d *schema.ResourceData) (string, error) {
+// BindToContext binds the current config.xml template to the given resource
+// data. Template-parse and execution failures are surfaced as structured
+// diagnostics, attributed to the `xml_template` attribute, rather than only
+// logged.
+func (c *ConfigXMLTemplate) BindToContext(ctx context.Context, d *schema.ResourceData) (string, diag.Diagnostics) {
 
 	if c == nil {
 		log.Printf("[ERROR] jenkins::xml - invalid config.xml template object")
-		return "", fmt.Errorf("Invalid config.xml template object")
+		return "", diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid config.xml template object",
+		}}
 	}
 
 	log.Printf("[DEBUG] jenkins::xml - binding template:\n%s", c.data)
 
-	// create and parse the config.xml template
-	tpl, err := template.New("template").Parse(c.data)
+	// create and parse the config.xml template. `include` is bound after
+	// tpl exists, since it needs to execute named templates against itself.
+	var tpl *template.Template
+	funcMap := templateFuncMap()
+	funcMap["include"] = func(name string, data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	tpl, err := template.New("template").Funcs(funcMap).Parse(c.data)
 	if err != nil {
 		log.Printf("[ERROR] jenkins::xml - error parsing template: %v", err)
-		return "", err
-	}
-
-	// Job contains all the data pertaining to a Jenkins job, in a format that is
-	// easy to use with Golang text/templates
-	type job struct {
-		Name                      string
-		Description               string
-		DisplayName               string
-		TriggerRemotelyToken      string
-		Disabled                  bool
-		MasterMergeTriggering     bool
-		Permissions               []string
-		Parameters                []map[string]string
-		BranchPushTriggering      map[string]string
-		PrTriggeringGhpr          map[string]string
-		PrTriggeringGhIntegration map[string]string
-		Jenkinsfile               map[string]string
-		Configuration             map[string]string
-	}
-
-	// now copy the input parameters into a data structure that is compatible
-	// with the config.xml template
-	j := &job{
-		Name:                      d.Get("name").(string),
-		Permissions:               []string{},
-		Configuration:             map[string]string{},
-		Jenkinsfile:               map[string]string{},
-		Parameters:                []map[string]string{},
-		PrTriggeringGhpr:          map[string]string{},
-		PrTriggeringGhIntegration: map[string]string{},
-		BranchPushTriggering:      map[string]string{},
-		MasterMergeTriggering:     false,
+		return "", diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Error parsing config.xml template",
+			Detail:        fmt.Sprintf("%s: %v", c.sourceDescription(), err),
+			AttributePath: cty.GetAttrPath("xml_template"),
+		}}
+	}
+
+	// Build the template data as a plain map rather than a fixed Go struct,
+	// so that adding a new Jenkins plugin knob no longer requires touching
+	// this file: anything placed under the `values` attribute shows up as
+	// `.Values.<key>` in the template. The attributes below are projected
+	// onto named top-level keys for backward compatibility with templates
+	// written against the old `job` struct.
+	data := map[string]interface{}{
+		"Name":                      d.Get("name").(string),
+		"Permissions":               []string{},
+		"Configuration":             map[string]string{},
+		"Jenkinsfile":               map[string]string{},
+		"Parameters":                []map[string]string{},
+		"PrTriggeringGhpr":          map[string]string{},
+		"PrTriggeringGhIntegration": map[string]string{},
+		"BranchPushTriggering":      map[string]string{},
+		"MasterMergeTriggering":     false,
+		"Values":                    map[string]interface{}{},
 	}
 	if value, ok := d.GetOk("display_name"); ok {
-		j.DisplayName = value.(string)
+		data["DisplayName"] = value.(string)
 	}
 	if value, ok := d.GetOk("description"); ok {
-		j.Description = value.(string)
+		data["Description"] = value.(string)
 	}
 	if value, ok := d.GetOk("trigger_remotely_token"); ok {
-		j.TriggerRemotelyToken = value.(string)
+		data["TriggerRemotelyToken"] = value.(string)
 	}
 	if value, ok := d.GetOk("disabled"); ok {
-		j.Disabled = value.(bool)
+		data["Disabled"] = value.(bool)
 	}
 	if value, ok := d.GetOk("master_merge_triggering"); ok {
-		j.MasterMergeTriggering = value.(bool)
+		data["MasterMergeTriggering"] = value.(bool)
 	}
 	if value, ok := d.GetOk("permissions"); ok {
 		value := value.(string)
 		elems := strings.Split(value, ",")
+		permissions := []string{}
 		for _, v := range elems {
-			j.Permissions = append(j.Permissions, v)
+			permissions = append(permissions, v)
 		}
+		data["Permissions"] = permissions
 	}
 	if value, ok := d.GetOk("configuration"); ok {
 		value := value.(map[string]interface{})
+		configuration := map[string]string{}
 		for k, v := range value {
-			j.Configuration[k] = v.(string)
+			configuration[k] = v.(string)
 		}
+		data["Configuration"] = configuration
 	}
 	if value, ok := d.GetOk("pr_triggering_ghpr"); ok {
 		value := value.(map[string]interface{})
+		prTriggeringGhpr := map[string]string{}
 		for k, v := range value {
-			j.PrTriggeringGhpr[k] = v.(string)
+			prTriggeringGhpr[k] = v.(string)
 		}
+		data["PrTriggeringGhpr"] = prTriggeringGhpr
 	}
 	if value, ok := d.GetOk("pr_triggering_gh_integration"); ok {
 		value := value.(map[string]interface{})
+		prTriggeringGhIntegration := map[string]string{}
 		for k, v := range value {
-			j.PrTriggeringGhIntegration[k] = v.(string)
+			prTriggeringGhIntegration[k] = v.(string)
 		}
+		data["PrTriggeringGhIntegration"] = prTriggeringGhIntegration
 	}
 	if value, ok := d.GetOk("parameter"); ok {
-		fmt.Println(value)
 		rawValue := value.([]interface{})
+		parameters := []map[string]string{}
 
 		for _, v := range rawValue {
 			configRaw := v.(map[string]interface{})
@@ -218,35 +265,59 @@ func (c *ConfigXMLTemplate) BindTo(d *schema.ResourceData) (string, error) {
 				config["Description"] = description.(string)
 			}
 
-			if d, ok := configRaw["default"]; ok {
-				config["Default"] = d.(string)
+			if def, ok := configRaw["default"]; ok {
+				config["Default"] = def.(string)
 			}
 
-			j.Parameters = append(j.Parameters, config)
+			parameters = append(parameters, config)
 		}
+		data["Parameters"] = parameters
 	}
 	if value, ok := d.GetOk("branch_push_triggering"); ok {
 		value := value.(map[string]interface{})
+		branchPushTriggering := map[string]string{}
 		for k, v := range value {
-			j.BranchPushTriggering[k] = v.(string)
+			branchPushTriggering[k] = v.(string)
 		}
+		data["BranchPushTriggering"] = branchPushTriggering
 	}
 	if value, ok := d.GetOk("jenkinsfile"); ok {
 		value := value.(map[string]interface{})
+		jenkinsfile := map[string]string{}
 		for k, v := range value {
-			j.Jenkinsfile[k] = v.(string)
+			jenkinsfile[k] = v.(string)
 		}
+		data["Jenkinsfile"] = jenkinsfile
+	}
+	if value, ok := d.GetOk("values"); ok {
+		value := value.(map[string]interface{})
+		data["Values"] = value
 	}
 
-	// apply the job object to the template
+	// apply the data to the template
 	var buffer bytes.Buffer
-	err = tpl.Execute(&buffer, j)
+	err = tpl.Execute(&buffer, data)
 	if err != nil {
 		log.Printf("[ERROR] jenkis::xml - error executing template: %v", err)
-		return "", err
+		return "", diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Error executing config.xml template",
+			Detail:        fmt.Sprintf("%s: %v", c.sourceDescription(), err),
+			AttributePath: cty.GetAttrPath("xml_template"),
+		}}
 	}
 
 	xml := buffer.String()
 	log.Printf("[DEBUG] jenkins::xml - bound template:\n%s", xml)
 	return xml, nil
 }
+
+// sourceDescription returns a human-readable identifier for the template's
+// origin, used to attribute diagnostics back to where the template came
+// from.
+func (c *ConfigXMLTemplate) sourceDescription() string {
+	if len(c.source) == 0 {
+		return "inline config.xml template"
+	}
+	return fmt.Sprintf("config.xml template %q", c.source)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestConfigXMLTemplateBindToContextPreservesXMLDeclaration(t *testing.T) {
+	tpl, err := NewConfigXMLTemplate(`<?xml version='1.0' encoding='UTF-8'?><project><displayName>{{ .Name }}</displayName></project>`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+		"name": {Type: schema.TypeString, Optional: true},
+	}, map[string]interface{}{
+		"name": "my-job",
+	})
+
+	xml, diags := tpl.BindToContext(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("unexpected error diagnostics: %v", diags)
+	}
+
+	if !strings.HasPrefix(xml, `<?xml version='1.0' encoding='UTF-8'?>`) {
+		t.Fatalf("expected the XML declaration to survive binding unescaped, got %q", xml)
+	}
+	if strings.Contains(xml, "&lt;") || strings.Contains(xml, "&gt;") {
+		t.Fatalf("expected no HTML entity-escaping of angle brackets, got %q", xml)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ConfigYAMLTemplate represents a Jenkins Configuration-as-Code (JCasC)
+// YAML template as an object. It mirrors ConfigXMLTemplate's loader, hash,
+// and bind semantics, but over YAML instead of XML.
+type ConfigYAMLTemplate struct {
+	source   string
+	data     string
+	hash     string
+	hashAlgo string
+}
+
+// NewConfigYAMLTemplate creates a new ConfigYAMLTemplate using the provided
+// address or inline/embedded data. It is a convenience wrapper around
+// NewConfigYAMLTemplateContext using context.Background() and the default
+// loader registry.
+func NewConfigYAMLTemplate(input string) (*ConfigYAMLTemplate, error) {
+	return NewConfigYAMLTemplateContext(context.Background(), input)
+}
+
+// NewConfigYAMLTemplateContext creates a new ConfigYAMLTemplate using the
+// provided address or inline/embedded data, dispatching to the
+// TemplateLoader registered for the address's scheme. If the address
+// carries an `@<algo>:<hash>` integrity pin, the loaded data is verified
+// against it before the template is accepted.
+func NewConfigYAMLTemplateContext(ctx context.Context, input string) (*ConfigYAMLTemplate, error) {
+
+	configuration := &ConfigYAMLTemplate{}
+	source := input
+
+	if m := pinnedHashPattern.FindStringSubmatch(input); m != nil {
+		hash := strings.ToLower(m[2])
+		algo, err := inferPinnedHashAlgo(m[1], hash)
+		if err != nil {
+			return nil, err
+		}
+		source = input[:len(input)-len(m[0])]
+		configuration.hashAlgo = algo
+		configuration.hash = hash
+	}
+
+	scheme := "inline"
+	if idx := strings.Index(source, "://"); idx >= 0 {
+		scheme = source[:idx]
+	}
+
+	if scheme == "inline" {
+		log.Printf("[DEBUG] jenkins::jcasc - template is inline: %q", source)
+		configuration.source = ""
+		configuration.data = source
+		return configuration, nil
+	}
+
+	loader, ok := templateLoader(scheme)
+	if !ok {
+		err := fmt.Errorf("no template loader registered for scheme %q", scheme)
+		log.Printf("[ERROR] jenkins::jcasc - %v", err)
+		return nil, err
+	}
+
+	data, err := loadTemplateViaCache(ctx, loader, source, "jenkins::jcasc")
+	if err != nil {
+		return nil, err
+	}
+
+	configuration.source = source
+	configuration.data = string(data)
+
+	if configuration.hash != "" {
+		computed, err := configuration.ComputedHash()
+		if err != nil {
+			return nil, err
+		}
+		if computed != configuration.hash {
+			err := fmt.Errorf("integrity check failed for %q: expected %s:%s, computed %s:%s",
+				source, configuration.hashAlgo, configuration.hash, configuration.hashAlgo, computed)
+			log.Printf("[ERROR] jenkins::jcasc - %v", err)
+			return nil, err
+		}
+	}
+
+	return configuration, nil
+}
+
+func (c *ConfigYAMLTemplate) GetTemplateID() (string, error) {
+	if c == nil {
+		log.Printf("[ERROR] jenkins::jcasc - invalid jcasc template object")
+		return "", fmt.Errorf("Invalid jcasc template object")
+	}
+
+	if len(c.source) == 0 {
+		return c.data, nil
+	}
+	hash, _ := c.ComputedHash()
+	return fmt.Sprintf("%s@%s", c.source, hash), nil
+}
+
+// RecordedHash returns the hash as recorded in the original input, if available.
+func (c *ConfigYAMLTemplate) RecordedHash() (string, error) {
+	if c == nil {
+		log.Printf("[ERROR] jenkins::jcasc - invalid jcasc template object")
+		return "", fmt.Errorf("Invalid jcasc template object")
+	}
+
+	return c.hash, nil
+}
+
+// ComputedHash returns the hash of the current (unbound) template, using
+// whichever algorithm was pinned on the source address, defaulting to MD5.
+func (c *ConfigYAMLTemplate) ComputedHash() (string, error) {
+	if c == nil {
+		log.Printf("[ERROR] jenkins::jcasc - invalid jcasc template object")
+		return "", fmt.Errorf("Invalid jcasc template object")
+	}
+	return computeHash(c.hashAlgo, c.data)
+}
+
+// BindToContext binds the current jcasc template to the given resource
+// data, using the same template engine and helper functions as
+// ConfigXMLTemplate.BindToContext. The template data holds only `.Name` and
+// `.Values`; the job attributes that ConfigXMLTemplate.BindToContext
+// projects onto top-level keys are not exposed.
+func (c *ConfigYAMLTemplate) BindToContext(ctx context.Context, d *schema.ResourceData) (string, diag.Diagnostics) {
+	if c == nil {
+		log.Printf("[ERROR] jenkins::jcasc - invalid jcasc template object")
+		return "", diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid jcasc template object",
+		}}
+	}
+
+	log.Printf("[DEBUG] jenkins::jcasc - binding template:\n%s", c.data)
+
+	var tpl *template.Template
+	funcMap := templateFuncMap()
+	funcMap["include"] = func(name string, data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	tpl, err := template.New("template").Funcs(funcMap).Parse(c.data)
+	if err != nil {
+		log.Printf("[ERROR] jenkins::jcasc - error parsing template: %v", err)
+		return "", diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Error parsing jcasc template",
+			Detail:        fmt.Sprintf("%s: %v", c.sourceDescription(), err),
+			AttributePath: cty.GetAttrPath("jcasc_template"),
+		}}
+	}
+
+	data := map[string]interface{}{
+		"Name":   d.Get("name").(string),
+		"Values": map[string]interface{}{},
+	}
+	if value, ok := d.GetOk("values"); ok {
+		data["Values"] = value.(map[string]interface{})
+	}
+
+	var buffer bytes.Buffer
+	err = tpl.Execute(&buffer, data)
+	if err != nil {
+		log.Printf("[ERROR] jenkins::jcasc - error executing template: %v", err)
+		return "", diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Error executing jcasc template",
+			Detail:        fmt.Sprintf("%s: %v", c.sourceDescription(), err),
+			AttributePath: cty.GetAttrPath("jcasc_template"),
+		}}
+	}
+
+	yaml := buffer.String()
+	log.Printf("[DEBUG] jenkins::jcasc - bound template:\n%s", yaml)
+	return yaml, nil
+}
+
+func (c *ConfigYAMLTemplate) sourceDescription() string {
+	if len(c.source) == 0 {
+		return "inline jcasc template"
+	}
+	return fmt.Sprintf("jcasc template %q", c.source)
+}